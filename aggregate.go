@@ -0,0 +1,275 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "sync"
+
+// Aggregator accumulates sequences from an AggregateNode's source into a
+// single synthesized result tuple.
+type Aggregator[T comparable] interface {
+	Init()
+	Accumulate(seq *Sequence[T])
+	Result() *Tuple[T]
+}
+
+// AggregateNode feeds every sequence from a single source through an
+// Aggregator and republishes the result as one synthesized sequence,
+// re-deriving it whenever the source changes so downstream beta nodes
+// always join against an up-to-date count/sum/min/max/collect.
+//
+// Its aggregator is not safe to share between AggregateNodes running
+// concurrently, but the node's own sequences/target/action lists are
+// guarded by mu on the same terms as AlphaNode and BetaNode.
+type AggregateNode[T comparable] struct {
+	mu             sync.Mutex
+	source         Node[T]
+	aggregator     Aggregator[T]
+	sequences      []*Sequence[T]
+	targets        []targetNode[T]
+	actions        []func(*Sequence[T])
+	retractActions []func(*Sequence[T])
+}
+
+func NewAggregateNode[T comparable](source Node[T], aggregator Aggregator[T]) *AggregateNode[T] {
+	return &AggregateNode[T]{source: source, aggregator: aggregator}
+}
+
+func (node *AggregateNode[T]) AddTarget(t Notifiable[T], index int) {
+	node.mu.Lock()
+	node.targets = append(node.targets, targetNode[T]{t, index})
+	node.mu.Unlock()
+}
+
+func (node *AggregateNode[T]) AddAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.actions = append(node.actions, a)
+	node.mu.Unlock()
+}
+
+func (node *AggregateNode[T]) AddRetractAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.retractActions = append(node.retractActions, a)
+	node.mu.Unlock()
+}
+
+func (node *AggregateNode[T]) EnumSequences(cb func(*Sequence[T])) {
+	node.mu.Lock()
+	sequences := make([]*Sequence[T], len(node.sequences))
+	copy(sequences, node.sequences)
+	node.mu.Unlock()
+	for _, s := range sequences {
+		cb(s)
+	}
+}
+
+// recompute re-derives the aggregate over the whole source and, if the
+// result changed, retracts the previous synthesized sequence and notifies
+// the new one. recompute itself is serialized by mu (the Aggregator is
+// stateful and not safe for concurrent Accumulate calls), but propagation
+// to targets and actions happens after mu is released.
+//
+// A result tuple with zero components (e.g. CollectAggregator over an
+// empty source) means the aggregator has nothing to report: it is treated
+// as no output at all rather than published, since a zero-component tuple
+// has no comps[0] for downstream alpha/beta indexing to key on.
+func (node *AggregateNode[T]) recompute() {
+	node.mu.Lock()
+	node.aggregator.Init()
+	node.source.EnumSequences(node.aggregator.Accumulate)
+	result := node.aggregator.Result()
+	var newSeq *Sequence[T]
+	if len(result.comps) > 0 {
+		newSeq = NewSequence(result)
+		if len(node.sequences) == 1 && node.sequences[0].Equals(newSeq) {
+			node.mu.Unlock()
+			return
+		}
+	} else if len(node.sequences) == 0 {
+		node.mu.Unlock()
+		return
+	}
+	old := node.sequences
+	if newSeq != nil {
+		node.sequences = []*Sequence[T]{newSeq}
+	} else {
+		node.sequences = nil
+	}
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	actions := make([]func(*Sequence[T]), len(node.actions))
+	copy(actions, node.actions)
+	retractActions := make([]func(*Sequence[T]), len(node.retractActions))
+	copy(retractActions, node.retractActions)
+	node.mu.Unlock()
+
+	for _, s := range old {
+		for _, t := range targets {
+			t.node.Retract(t.index, s)
+		}
+		for _, a := range retractActions {
+			a(s)
+		}
+	}
+	if newSeq == nil {
+		return
+	}
+	for _, t := range targets {
+		t.node.Notify(t.index, newSeq)
+	}
+	for _, a := range actions {
+		a(newSeq)
+	}
+}
+
+func (node *AggregateNode[T]) Notify(int, *Sequence[T]) {
+	node.recompute()
+}
+
+func (node *AggregateNode[T]) Retract(int, *Sequence[T]) {
+	node.recompute()
+}
+
+// CountAggregator counts the sequences it sees, converting the count to T
+// via toT (e.g. strconv.Itoa when T is string).
+type CountAggregator[T comparable] struct {
+	toT func(int) T
+	n   int
+}
+
+func NewCountAggregator[T comparable](toT func(int) T) *CountAggregator[T] {
+	return &CountAggregator[T]{toT: toT}
+}
+
+func (a *CountAggregator[T]) Init() {
+	a.n = 0
+}
+
+func (a *CountAggregator[T]) Accumulate(*Sequence[T]) {
+	a.n++
+}
+
+func (a *CountAggregator[T]) Result() *Tuple[T] {
+	return NewTuple(a.toT(a.n))
+}
+
+// CollectAggregator gathers the value extracted by key from every matching
+// sequence into a single tuple, one component per match.
+type CollectAggregator[T comparable] struct {
+	key    func(*Sequence[T]) T
+	values []T
+}
+
+func NewCollectAggregator[T comparable](key func(*Sequence[T]) T) *CollectAggregator[T] {
+	return &CollectAggregator[T]{key: key}
+}
+
+func (a *CollectAggregator[T]) Init() {
+	a.values = nil
+}
+
+func (a *CollectAggregator[T]) Accumulate(seq *Sequence[T]) {
+	a.values = append(a.values, a.key(seq))
+}
+
+func (a *CollectAggregator[T]) Result() *Tuple[T] {
+	return NewTuple(a.values...)
+}
+
+// Number constrains the value extracted by Sum/Min/MaxAggregator.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// SumAggregator sums the numeric value extracted by key from every
+// matching sequence, converting the total to T via toT.
+type SumAggregator[N Number, T comparable] struct {
+	key func(*Sequence[T]) N
+	toT func(N) T
+	sum N
+}
+
+func NewSumAggregator[N Number, T comparable](key func(*Sequence[T]) N, toT func(N) T) *SumAggregator[N, T] {
+	return &SumAggregator[N, T]{key: key, toT: toT}
+}
+
+func (a *SumAggregator[N, T]) Init() {
+	a.sum = 0
+}
+
+func (a *SumAggregator[N, T]) Accumulate(seq *Sequence[T]) {
+	a.sum += a.key(seq)
+}
+
+func (a *SumAggregator[N, T]) Result() *Tuple[T] {
+	return NewTuple(a.toT(a.sum))
+}
+
+// MinAggregator tracks the smallest numeric value extracted by key,
+// converting it to T via toT. Result is the zero value of T if no
+// sequence was ever accumulated.
+type MinAggregator[N Number, T comparable] struct {
+	key  func(*Sequence[T]) N
+	toT  func(N) T
+	min  N
+	seen bool
+}
+
+func NewMinAggregator[N Number, T comparable](key func(*Sequence[T]) N, toT func(N) T) *MinAggregator[N, T] {
+	return &MinAggregator[N, T]{key: key, toT: toT}
+}
+
+func (a *MinAggregator[N, T]) Init() {
+	a.seen = false
+}
+
+func (a *MinAggregator[N, T]) Accumulate(seq *Sequence[T]) {
+	v := a.key(seq)
+	if !a.seen || v < a.min {
+		a.min = v
+		a.seen = true
+	}
+}
+
+func (a *MinAggregator[N, T]) Result() *Tuple[T] {
+	if !a.seen {
+		var zero T
+		return NewTuple(zero)
+	}
+	return NewTuple(a.toT(a.min))
+}
+
+// MaxAggregator tracks the largest numeric value extracted by key,
+// converting it to T via toT. Result is the zero value of T if no
+// sequence was ever accumulated.
+type MaxAggregator[N Number, T comparable] struct {
+	key  func(*Sequence[T]) N
+	toT  func(N) T
+	max  N
+	seen bool
+}
+
+func NewMaxAggregator[N Number, T comparable](key func(*Sequence[T]) N, toT func(N) T) *MaxAggregator[N, T] {
+	return &MaxAggregator[N, T]{key: key, toT: toT}
+}
+
+func (a *MaxAggregator[N, T]) Init() {
+	a.seen = false
+}
+
+func (a *MaxAggregator[N, T]) Accumulate(seq *Sequence[T]) {
+	v := a.key(seq)
+	if !a.seen || v > a.max {
+		a.max = v
+		a.seen = true
+	}
+}
+
+func (a *MaxAggregator[N, T]) Result() *Tuple[T] {
+	if !a.seen {
+		var zero T
+		return NewTuple(zero)
+	}
+	return NewTuple(a.toT(a.max))
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "testing"
+
+// TestCollectAggregatorEmptySourceDoesNotPanicDownstream reproduces a panic
+// CollectAggregator used to trigger: once its source empties out, its
+// Result() is a zero-component tuple, which a downstream beta node's
+// indexing (keyed on comps[0]) cannot handle. recompute must treat that as
+// no output instead of publishing it.
+func TestCollectAggregatorEmptySourceDoesNotPanicDownstream(t *testing.T) {
+	source := NewAlphaNode[int]("source/1")
+	other := NewAlphaNode[int]("other/1")
+	agg := NewAggregateNode[int](source, NewCollectAggregator(func(seq *Sequence[int]) int {
+		return seq.Get(0).Get(0)
+	}))
+
+	join := NewBetaNode[int]()
+	join.AddSource(agg)
+	join.AddSource(other)
+	join.AddBinding(Binding[int]{Tuple1: 0, Comp1: 0, Tuple2: 0, Comp2: 0})
+	source.AddTarget(agg, 0)
+	agg.AddTarget(join, 0)
+	other.AddTarget(join, 1)
+
+	source.AddTuple(NewTuple(1))
+	source.RemoveTuple(NewTuple(1))
+
+	var results []*Sequence[int]
+	agg.EnumSequences(func(seq *Sequence[int]) { results = append(results, seq) })
+	if len(results) != 0 {
+		t.Fatalf("want no aggregate result once the source is empty, got %v", results)
+	}
+}
+
+// TestCollectAggregatorRetractToEmpty checks that emptying the source
+// retracts the previously published result rather than silently leaving it
+// in place.
+func TestCollectAggregatorRetractToEmpty(t *testing.T) {
+	source := NewAlphaNode[int]("source/1")
+	agg := NewAggregateNode[int](source, NewCollectAggregator(func(seq *Sequence[int]) int {
+		return seq.Get(0).Get(0)
+	}))
+	source.AddTarget(agg, 0)
+
+	var retracted bool
+	agg.AddRetractAction(func(*Sequence[int]) { retracted = true })
+
+	source.AddTuple(NewTuple(1))
+	var before []*Sequence[int]
+	agg.EnumSequences(func(seq *Sequence[int]) { before = append(before, seq) })
+	if len(before) != 1 {
+		t.Fatalf("want 1 aggregate result after seeding the source, got %d", len(before))
+	}
+
+	source.RemoveTuple(NewTuple(1))
+	var after []*Sequence[int]
+	agg.EnumSequences(func(seq *Sequence[int]) { after = append(after, seq) })
+	if len(after) != 0 {
+		t.Fatalf("want no aggregate result once the source is empty again, got %v", after)
+	}
+	if !retracted {
+		t.Fatal("want the previous result to be retracted when the source empties out")
+	}
+}
+
+// TestCountAggregatorEmptySourcePublishesZero checks that a zero-component
+// edge case in one aggregator (CollectAggregator) doesn't change the
+// behaviour of another that always has a result to report, even over an
+// empty source.
+func TestCountAggregatorEmptySourcePublishesZero(t *testing.T) {
+	source := NewAlphaNode[int]("source/1")
+	agg := NewAggregateNode[int](source, NewCountAggregator(func(n int) int { return n }))
+	source.AddTarget(agg, 0)
+	agg.Notify(0, nil)
+
+	var results []*Sequence[int]
+	agg.EnumSequences(func(seq *Sequence[int]) { results = append(results, seq) })
+	if len(results) != 1 || results[0].Get(0).Get(0) != 0 {
+		t.Fatalf("want a single result (0) for a count over an empty source, got %v", results)
+	}
+}
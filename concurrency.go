@@ -0,0 +1,151 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// SyncNetwork wraps a Network so AddTuple/RemoveTuple can be called from
+// multiple goroutines at once. AlphaNode, BetaNode, NotNode, and
+// AggregateNode are already internally lock-safe (see their mu fields);
+// SyncNetwork adds a worker pool so that the join propagation triggered by
+// unrelated facts can run concurrently instead of each AddTuple call
+// blocking the next.
+//
+// Facts are sharded across the pool by functor/arity signature: every
+// AddTuple/RemoveTuple call for a given signature lands on the same worker,
+// so the nodes for that signature are only ever touched by one goroutine at
+// a time and never contend with themselves under concurrent dispatch.
+// Different signatures are sharded to different workers (subject to hash
+// collisions) and genuinely propagate in parallel. Ordering across
+// signatures is unspecified, and so is ordering within a signature relative
+// to other goroutines calling AddTuple/RemoveTuple concurrently; calls made
+// from the same goroutine for the same signature keep their relative order,
+// since they land on the same worker's queue in the order sent. A functor
+// marked serial via MarkSerial bypasses the pool entirely and runs
+// synchronously on the caller's goroutine. Flush blocks until every
+// dispatched fact has finished propagating.
+type SyncNetwork[T comparable] struct {
+	net *Network[T]
+
+	queues []chan func()
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	serial map[string]bool
+}
+
+// NewSyncNetwork creates a SyncNetwork backed by a fresh Network, sharding
+// work across a pool of the given number of workers. A size of 0 or less
+// runs every fact synchronously on the caller's goroutine, same as
+// MarkSerial for every functor.
+func NewSyncNetwork[T comparable](workers int) *SyncNetwork[T] {
+	sn := &SyncNetwork[T]{
+		net:    NewNetwork[T](),
+		serial: make(map[string]bool),
+	}
+	if workers > 0 {
+		sn.queues = make([]chan func(), workers)
+		for i := range sn.queues {
+			queue := make(chan func())
+			sn.queues[i] = queue
+			go runQueue(queue)
+		}
+	}
+	return sn
+}
+
+func runQueue(queue chan func()) {
+	for job := range queue {
+		job()
+	}
+}
+
+// Network returns the underlying Network, e.g. to call AlphaNode/AddNode
+// directly while building the rule set up front. Doing so concurrently
+// with AddTuple/RemoveTuple calls is the caller's responsibility to
+// serialize, same as it would be for a plain Network.
+func (sn *SyncNetwork[T]) Network() *Network[T] {
+	return sn.net
+}
+
+// MarkSerial excludes functor/arity sig (as produced by fmt.Sprintf("%s/%d",
+// functor, arity)) from the worker pool: AddTuple/RemoveTuple calls for it
+// always run synchronously on the caller's goroutine. Use this for facts
+// whose actions must observe total order, at the cost of losing
+// parallelism for that functor.
+func (sn *SyncNetwork[T]) MarkSerial(sig string) {
+	sn.mu.Lock()
+	sn.serial[sig] = true
+	sn.mu.Unlock()
+}
+
+func (sn *SyncNetwork[T]) isSerial(sig string) bool {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+	return sn.serial[sig]
+}
+
+// queueFor returns the worker queue sig is sharded to, so that every call
+// for a given signature always lands on the same worker.
+func (sn *SyncNetwork[T]) queueFor(sig string) chan func() {
+	h := fnv.New32a()
+	h.Write([]byte(sig))
+	return sn.queues[h.Sum32()%uint32(len(sn.queues))]
+}
+
+func (sn *SyncNetwork[T]) dispatch(sig string, job func()) {
+	sn.wg.Add(1)
+	sn.queueFor(sig) <- func() {
+		defer sn.wg.Done()
+		job()
+	}
+}
+
+// AddTuple adds a tuple the same way Network.AddTuple does. Unless the
+// functor/arity has been marked serial, or the pool was created with 0
+// workers, the propagation runs on a pool worker and AddTuple returns
+// before it completes; call Flush to wait for it.
+func (sn *SyncNetwork[T]) AddTuple(functor string, comps ...T) {
+	sig := functorSig(functor, len(comps))
+	if sn.queues == nil || sn.isSerial(sig) {
+		sn.net.AddTuple(functor, comps...)
+		return
+	}
+	sn.dispatch(sig, func() {
+		sn.net.AddTuple(functor, comps...)
+	})
+}
+
+// RemoveTuple retracts a tuple the same way Network.RemoveTuple does,
+// under the same serial/pool rules as AddTuple. Network.RemoveTuple's
+// presence result is discarded here: when dispatched to the pool, it isn't
+// known at call time. A caller that needs it should mark the functor
+// serial (or call Flush, then call Network.RemoveTuple directly).
+func (sn *SyncNetwork[T]) RemoveTuple(functor string, comps ...T) {
+	sig := functorSig(functor, len(comps))
+	if sn.queues == nil || sn.isSerial(sig) {
+		sn.net.RemoveTuple(functor, comps...)
+		return
+	}
+	sn.dispatch(sig, func() {
+		sn.net.RemoveTuple(functor, comps...)
+	})
+}
+
+// Flush blocks until every fact dispatched to the worker pool by AddTuple
+// or RemoveTuple has finished propagating through the network. Call it
+// before reading results (e.g. via EnumSequences) to get a consistent
+// view.
+func (sn *SyncNetwork[T]) Flush() {
+	sn.wg.Wait()
+}
+
+func functorSig(functor string, arity int) string {
+	return fmt.Sprintf("%s/%d", functor, arity)
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// buildTransitiveClosureGroup wires up path(X,Z) :- path(X,Y), edge(Y,Z)
+// (plus the path(X,Y) :- edge(X,Y) base case) over a group's own edge/path
+// alpha nodes, identified by group so that independent groups added to the
+// same network never join against each other and can be propagated in
+// parallel.
+func buildTransitiveClosureGroup(net *Network[int], group int) {
+	edgeSig := fmt.Sprintf("edge%d", group)
+	pathSig := fmt.Sprintf("path%d", group)
+
+	edge := net.AlphaNode(fmt.Sprintf("%s/2", edgeSig))
+	path := net.AlphaNode(fmt.Sprintf("%s/2", pathSig))
+
+	edge.AddAction(func(seq *Sequence[int]) {
+		t := seq.Get(0)
+		net.AddTuple(pathSig, t.Get(0), t.Get(1))
+	})
+
+	join := NewBetaNode[int]()
+	join.AddSource(path)
+	join.AddSource(edge)
+	join.AddBinding(Binding[int]{Tuple1: 0, Comp1: 1, Tuple2: 0, Comp2: 0})
+	path.AddTarget(join, 0)
+	edge.AddTarget(join, 1)
+	join.AddAction(func(seq *Sequence[int]) {
+		net.AddTuple(pathSig, seq.Get(0).Get(0), seq.Get(1).Get(1))
+	})
+}
+
+const (
+	benchGroups   = 8
+	benchChainLen = 50
+)
+
+func addChainEdges(add func(functor string, comps ...int)) {
+	for g := 0; g < benchGroups; g++ {
+		edgeSig := fmt.Sprintf("edge%d", g)
+		for x := 0; x < benchChainLen; x++ {
+			add(edgeSig, x, x+1)
+		}
+	}
+}
+
+// BenchmarkTransitiveClosureSerial drives benchGroups independent
+// transitive-closure chains through the plain, single-threaded Network.
+func BenchmarkTransitiveClosureSerial(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		net := NewNetwork[int]()
+		for g := 0; g < benchGroups; g++ {
+			buildTransitiveClosureGroup(net, g)
+		}
+		addChainEdges(net.AddTuple)
+	}
+}
+
+// BenchmarkTransitiveClosureSync drives the same workload through a
+// SyncNetwork with a worker per CPU, so the benchGroups chains (independent
+// of one another) propagate concurrently instead of one after another.
+func BenchmarkTransitiveClosureSync(b *testing.B) {
+	workers := runtime.GOMAXPROCS(0)
+	for i := 0; i < b.N; i++ {
+		sn := NewSyncNetwork[int](workers)
+		for g := 0; g < benchGroups; g++ {
+			buildTransitiveClosureGroup(sn.Network(), g)
+		}
+		addChainEdges(sn.AddTuple)
+		sn.Flush()
+	}
+}
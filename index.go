@@ -0,0 +1,123 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+// RangeEnumerable is implemented by a beta node's source to let Notify
+// resolve a Between/Lt/Le/Gt/Ge binding by walking an ordered index
+// instead of scanning every sequence. tupleIdx/compIdx address the bound
+// position the same way Binding's Tuple/Comp fields do. AlphaNode
+// implements it for any position that has had AddOrderedIndex called on
+// it (tupleIdx is always 0, since an AlphaNode's sequences hold a single
+// tuple), falling back to a full scan for positions that don't; BetaNode
+// implements it the same way for positions that have had its own
+// AddOrderedIndex called.
+type RangeEnumerable[T comparable] interface {
+	EnumRange(tupleIdx, compIdx int, lo, hi T, cb func(*Sequence[T]))
+	EnumGreater(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T]))
+	EnumLess(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T]))
+}
+
+// AddOrderedIndex builds a red-black tree index on position pos, ordered
+// by less, over every tuple already in the node, and keeps it up to date
+// as tuples are added and removed. It lets BetaNode.Notify resolve a
+// Between/Lt/Le/Gt/Ge binding against pos in O(log n + k) instead of
+// scanning every tuple.
+func (node *AlphaNode[T]) AddOrderedIndex(pos int, less func(a, b T) bool) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if node.orderedIndices == nil {
+		node.orderedIndices = make(map[int]*OrderedIndex[T, *Tuple[T]])
+	}
+	idx := NewOrderedIndex[T, *Tuple[T]](less)
+	for _, tuple := range node.tuples {
+		idx.Insert(tuple.comps[pos], tuple)
+	}
+	node.orderedIndices[pos] = idx
+}
+
+// EnumRange enumerates every tuple whose component at compIdx falls in
+// [lo, hi], each wrapped in its own one-tuple sequence, in ascending order
+// of that component. tupleIdx is ignored (an AlphaNode's sequences always
+// hold a single tuple, at index 0). If compIdx has no ordered index, it
+// falls back to enumerating every tuple in the node; the caller
+// (BetaNode.Notify) re-checks the binding against each candidate, so a
+// broader candidate set is just a missed optimization, not incorrect.
+//
+// The matching tuples are snapshotted into a slice while node's lock is
+// held, then cb is called against the snapshot with the lock released, so
+// a callback that loops back into the node cannot deadlock against it.
+func (node *AlphaNode[T]) EnumRange(tupleIdx, compIdx int, lo, hi T, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[compIdx]
+	if !ok {
+		tuples := make([]*Tuple[T], len(node.tuples))
+		copy(tuples, node.tuples)
+		node.mu.RUnlock()
+		for _, t := range tuples {
+			cb(NewSequence(t))
+		}
+		return
+	}
+	var matches []*Tuple[T]
+	idx.Range(lo, hi, func(_ T, tuple *Tuple[T]) {
+		matches = append(matches, tuple)
+	})
+	node.mu.RUnlock()
+	for _, t := range matches {
+		cb(NewSequence(t))
+	}
+}
+
+// EnumGreater enumerates every tuple whose component at compIdx is greater
+// than bound (or, if inclusive, greater than or equal to it), in ascending
+// order of that component. It otherwise behaves like EnumRange, including
+// the full-scan fallback when compIdx has no ordered index.
+func (node *AlphaNode[T]) EnumGreater(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[compIdx]
+	if !ok {
+		tuples := make([]*Tuple[T], len(node.tuples))
+		copy(tuples, node.tuples)
+		node.mu.RUnlock()
+		for _, t := range tuples {
+			cb(NewSequence(t))
+		}
+		return
+	}
+	var matches []*Tuple[T]
+	idx.RangeFrom(bound, inclusive, func(_ T, tuple *Tuple[T]) {
+		matches = append(matches, tuple)
+	})
+	node.mu.RUnlock()
+	for _, t := range matches {
+		cb(NewSequence(t))
+	}
+}
+
+// EnumLess enumerates every tuple whose component at compIdx is less than
+// bound (or, if inclusive, less than or equal to it), in ascending order
+// of that component. It otherwise behaves like EnumRange, including the
+// full-scan fallback when compIdx has no ordered index.
+func (node *AlphaNode[T]) EnumLess(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[compIdx]
+	if !ok {
+		tuples := make([]*Tuple[T], len(node.tuples))
+		copy(tuples, node.tuples)
+		node.mu.RUnlock()
+		for _, t := range tuples {
+			cb(NewSequence(t))
+		}
+		return
+	}
+	var matches []*Tuple[T]
+	idx.RangeTo(bound, inclusive, func(_ T, tuple *Tuple[T]) {
+		matches = append(matches, tuple)
+	})
+	node.mu.RUnlock()
+	for _, t := range matches {
+		cb(NewSequence(t))
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "testing"
+
+func lessInt(a, b int) bool { return a < b }
+
+// TestOrderedIndexRangeFromTo checks RangeFrom/RangeTo's inclusive and
+// exclusive bounds against a small tree with duplicate-free keys.
+func TestOrderedIndexRangeFromTo(t *testing.T) {
+	idx := NewOrderedIndex[int, int](lessInt)
+	for _, k := range []int{5, 1, 9, 3, 7, 2, 8, 4, 6} {
+		idx.Insert(k, k)
+	}
+
+	collect := func(f func(cb func(key int, value int))) []int {
+		var got []int
+		f(func(_, v int) { got = append(got, v) })
+		return got
+	}
+
+	wantEq := func(t *testing.T, name string, got, want []int) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: want %v, got %v", name, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: want %v, got %v", name, want, got)
+			}
+		}
+	}
+
+	wantEq(t, "RangeFrom(5, exclusive)",
+		collect(func(cb func(int, int)) { idx.RangeFrom(5, false, cb) }),
+		[]int{6, 7, 8, 9})
+	wantEq(t, "RangeFrom(5, inclusive)",
+		collect(func(cb func(int, int)) { idx.RangeFrom(5, true, cb) }),
+		[]int{5, 6, 7, 8, 9})
+	wantEq(t, "RangeTo(5, exclusive)",
+		collect(func(cb func(int, int)) { idx.RangeTo(5, false, cb) }),
+		[]int{1, 2, 3, 4})
+	wantEq(t, "RangeTo(5, inclusive)",
+		collect(func(cb func(int, int)) { idx.RangeTo(5, true, cb) }),
+		[]int{1, 2, 3, 4, 5})
+	wantEq(t, "RangeFrom(9, exclusive)",
+		collect(func(cb func(int, int)) { idx.RangeFrom(9, false, cb) }),
+		nil)
+	wantEq(t, "RangeTo(1, exclusive)",
+		collect(func(cb func(int, int)) { idx.RangeTo(1, false, cb) }),
+		nil)
+}
+
+// buildOrderedJoin wires a beta join between a "left" alpha node (with an
+// ordered index on position 0) and a "right" alpha node with a single
+// binding of the given Op, and returns the join along with the left node
+// to seed.
+func buildOrderedJoin(op Op) (*BetaNode[int], *AlphaNode[int], *AlphaNode[int]) {
+	left := NewAlphaNode[int]("left/1")
+	left.AddOrderedIndex(0, lessInt)
+	right := NewAlphaNode[int]("right/1")
+
+	join := NewBetaNode[int]()
+	join.AddSource(left)
+	join.AddSource(right)
+	join.AddBinding(Binding[int]{Tuple1: 0, Comp1: 0, Tuple2: 0, Comp2: 0, Op: op, Less: lessInt})
+	left.AddTarget(join, 0)
+	right.AddTarget(join, 1)
+	return join, left, right
+}
+
+func joinedLeftValues(join *BetaNode[int]) []int {
+	var got []int
+	join.EnumSequences(func(seq *Sequence[int]) { got = append(got, seq.Get(0).Get(0)) })
+	return got
+}
+
+// TestBetaNodeLtLeGtGeBindings checks that Lt/Le/Gt/Ge bindings resolve
+// correctly when the right-hand side arrives after the left-hand ordered
+// index is already populated (the path that can use the index).
+func TestBetaNodeLtLeGtGeBindings(t *testing.T) {
+	cases := []struct {
+		op   Op
+		want []int
+	}{
+		{Lt, []int{1, 2}},
+		{Le, []int{1, 2, 3}},
+		{Gt, []int{4, 5}},
+		{Ge, []int{3, 4, 5}},
+	}
+	for _, c := range cases {
+		join, left, right := buildOrderedJoin(c.op)
+		for _, v := range []int{1, 2, 3, 4, 5} {
+			left.AddTuple(NewTuple(v))
+		}
+		right.AddTuple(NewTuple(3))
+
+		got := joinedLeftValues(join)
+		if len(got) != len(c.want) {
+			t.Fatalf("op=%v: want %v left values joined, got %v", c.op, c.want, got)
+		}
+		seen := make(map[int]bool)
+		for _, v := range got {
+			seen[v] = true
+		}
+		for _, v := range c.want {
+			if !seen[v] {
+				t.Fatalf("op=%v: want %v joined, got %v", c.op, c.want, got)
+			}
+		}
+	}
+}
+
+// TestBetaNodeOrderedIndex checks that a BetaNode can itself hold an
+// ordered index over its derived sequences and resolve EnumRange/
+// EnumGreater/EnumLess against it for a downstream join.
+func TestBetaNodeOrderedIndex(t *testing.T) {
+	left := NewAlphaNode[int]("left/1")
+	right := NewAlphaNode[int]("right/1")
+	join := NewBetaNode[int]()
+	join.AddSource(left)
+	join.AddSource(right)
+	join.AddBinding(Binding[int]{Tuple1: 0, Comp1: 0, Tuple2: 0, Comp2: 0})
+	left.AddTarget(join, 0)
+	right.AddTarget(join, 1)
+	// join's derived sequences are [leftTuple, rightTuple]; index position
+	// (tuple 0, comp 0) the same way AlphaNode indexes its single tuple.
+	join.AddOrderedIndex(0, 0, lessInt)
+
+	for _, v := range []int{1, 2, 3, 4, 5} {
+		left.AddTuple(NewTuple(v))
+		right.AddTuple(NewTuple(v))
+	}
+
+	var viaRange []int
+	join.EnumRange(0, 0, 2, 4, func(seq *Sequence[int]) { viaRange = append(viaRange, seq.Get(0).Get(0)) })
+	if len(viaRange) != 3 {
+		t.Fatalf("want 3 sequences in [2,4], got %v", viaRange)
+	}
+
+	var viaGreater []int
+	join.EnumGreater(0, 0, 3, false, func(seq *Sequence[int]) { viaGreater = append(viaGreater, seq.Get(0).Get(0)) })
+	if len(viaGreater) != 2 {
+		t.Fatalf("want 2 sequences > 3, got %v", viaGreater)
+	}
+
+	var viaLess []int
+	join.EnumLess(0, 0, 3, true, func(seq *Sequence[int]) { viaLess = append(viaLess, seq.Get(0).Get(0)) })
+	if len(viaLess) != 3 {
+		t.Fatalf("want 3 sequences <= 3, got %v", viaLess)
+	}
+
+	left.RemoveTuple(NewTuple(3))
+	right.RemoveTuple(NewTuple(3))
+	var afterRemove []int
+	join.EnumRange(0, 0, 2, 4, func(seq *Sequence[int]) { afterRemove = append(afterRemove, seq.Get(0).Get(0)) })
+	if len(afterRemove) != 2 {
+		t.Fatalf("want 2 sequences in [2,4] after removing 3, got %v", afterRemove)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "sync"
+
+// NotNode propagates a sequence from its positive source only while no
+// sequence from its negative source satisfies its bindings against it. It
+// revises its output as sequences are notified or retracted on either
+// side, so "match X only when no Y exists such that ..." rules stay
+// correct as facts change.
+//
+// Its candidates and sequences lists, and its target/action lists, are
+// guarded by mu on the same terms as AlphaNode and BetaNode: propagation
+// to targets and actions always happens after mu is released.
+type NotNode[T comparable] struct {
+	mu             sync.RWMutex
+	positive       Node[T]
+	negative       Node[T]
+	bindings       []Binding[T]
+	candidates     []*Sequence[T]
+	sequences      []*Sequence[T]
+	targets        []targetNode[T]
+	actions        []func(*Sequence[T])
+	retractActions []func(*Sequence[T])
+}
+
+func NewNotNode[T comparable](positive, negative Node[T]) *NotNode[T] {
+	return &NotNode[T]{positive: positive, negative: negative}
+}
+
+func (node *NotNode[T]) AddBinding(b Binding[T]) {
+	node.mu.Lock()
+	node.bindings = append(node.bindings, b)
+	node.mu.Unlock()
+}
+
+func (node *NotNode[T]) AddTarget(t Notifiable[T], index int) {
+	node.mu.Lock()
+	node.targets = append(node.targets, targetNode[T]{t, index})
+	node.mu.Unlock()
+}
+
+func (node *NotNode[T]) AddAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.actions = append(node.actions, a)
+	node.mu.Unlock()
+}
+
+func (node *NotNode[T]) AddRetractAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.retractActions = append(node.retractActions, a)
+	node.mu.Unlock()
+}
+
+func (node *NotNode[T]) EnumSequences(cb func(*Sequence[T])) {
+	node.mu.RLock()
+	sequences := make([]*Sequence[T], len(node.sequences))
+	copy(sequences, node.sequences)
+	node.mu.RUnlock()
+	for _, s := range sequences {
+		cb(s)
+	}
+}
+
+func (node *NotNode[T]) blocks(seq, blocker *Sequence[T], bindings []Binding[T]) bool {
+	for _, b := range bindings {
+		if !checkBinding(seq, blocker, b) {
+			return false
+		}
+	}
+	return true
+}
+
+func (node *NotNode[T]) isExcluded(seq *Sequence[T], bindings []Binding[T]) bool {
+	excluded := false
+	node.negative.EnumSequences(func(blocker *Sequence[T]) {
+		if !excluded && node.blocks(seq, blocker, bindings) {
+			excluded = true
+		}
+	})
+	return excluded
+}
+
+func (node *NotNode[T]) included(seq *Sequence[T]) bool {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	for _, s := range node.sequences {
+		if s.Equals(seq) {
+			return true
+		}
+	}
+	return false
+}
+
+func (node *NotNode[T]) include(seq *Sequence[T]) {
+	node.mu.Lock()
+	node.sequences = append(node.sequences, seq)
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	actions := make([]func(*Sequence[T]), len(node.actions))
+	copy(actions, node.actions)
+	node.mu.Unlock()
+
+	for _, t := range targets {
+		t.node.Notify(t.index, seq)
+	}
+	for _, a := range actions {
+		a(seq)
+	}
+}
+
+func (node *NotNode[T]) exclude(seq *Sequence[T]) {
+	node.mu.Lock()
+	pos := -1
+	for i, s := range node.sequences {
+		if s.Equals(seq) {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		node.mu.Unlock()
+		return
+	}
+	removed := node.sequences[pos]
+	node.sequences = append(node.sequences[:pos], node.sequences[pos+1:]...)
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	retractActions := make([]func(*Sequence[T]), len(node.retractActions))
+	copy(retractActions, node.retractActions)
+	node.mu.Unlock()
+
+	for _, t := range targets {
+		t.node.Retract(t.index, removed)
+	}
+	for _, a := range retractActions {
+		a(removed)
+	}
+}
+
+func (node *NotNode[T]) addCandidate(seq *Sequence[T]) {
+	node.mu.Lock()
+	node.candidates = append(node.candidates, seq)
+	node.mu.Unlock()
+}
+
+func (node *NotNode[T]) removeCandidate(seq *Sequence[T]) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	for i, c := range node.candidates {
+		if c.Equals(seq) {
+			node.candidates = append(node.candidates[:i], node.candidates[i+1:]...)
+			return
+		}
+	}
+}
+
+func (node *NotNode[T]) candidateSnapshot() []*Sequence[T] {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	out := make([]*Sequence[T], len(node.candidates))
+	copy(out, node.candidates)
+	return out
+}
+
+func (node *NotNode[T]) bindingSnapshot() []Binding[T] {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	out := make([]Binding[T], len(node.bindings))
+	copy(out, node.bindings)
+	return out
+}
+
+// Notify handles a sequence arriving on one of the two sources: index 0 is
+// the positive source, index 1 is the negative one. A new positive
+// sequence is included unless a negative sequence already blocks it; a new
+// negative sequence excludes any output it now blocks.
+func (node *NotNode[T]) Notify(index int, seq *Sequence[T]) {
+	bindings := node.bindingSnapshot()
+	if index == 0 {
+		node.addCandidate(seq)
+		if !node.isExcluded(seq, bindings) {
+			node.include(seq)
+		}
+		return
+	}
+	for _, c := range node.candidateSnapshot() {
+		if node.included(c) && node.blocks(c, seq, bindings) {
+			node.exclude(c)
+		}
+	}
+}
+
+// Retract handles a sequence disappearing from one of the two sources: a
+// retracted positive sequence is simply dropped, while a retracted
+// negative sequence may un-block candidates that no remaining blocker
+// excludes, re-including them.
+func (node *NotNode[T]) Retract(index int, seq *Sequence[T]) {
+	if index == 0 {
+		node.removeCandidate(seq)
+		node.exclude(seq)
+		return
+	}
+	bindings := node.bindingSnapshot()
+	for _, c := range node.candidateSnapshot() {
+		if !node.included(c) && !node.isExcluded(c, bindings) {
+			node.include(c)
+		}
+	}
+}
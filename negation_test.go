@@ -0,0 +1,105 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "testing"
+
+// eqBinding is the Eq binding shared by these tests: the candidate's tuple 0,
+// component 0 must equal the blocker's tuple 0, component 0.
+var eqBinding = Binding[int]{Tuple1: 0, Comp1: 0, Tuple2: 0, Comp2: 0}
+
+func newNotNode() (*NotNode[int], *AlphaNode[int], *AlphaNode[int]) {
+	positive := NewAlphaNode[int]("positive/1")
+	negative := NewAlphaNode[int]("negative/1")
+	node := NewNotNode[int](positive, negative)
+	node.AddBinding(eqBinding)
+	positive.AddTarget(node, 0)
+	negative.AddTarget(node, 1)
+	return node, positive, negative
+}
+
+func notNodeValues(node *NotNode[int]) []int {
+	var got []int
+	node.EnumSequences(func(seq *Sequence[int]) { got = append(got, seq.Get(0).Get(0)) })
+	return got
+}
+
+// TestNotNodePositiveThenBlockingNegative checks that a positive candidate,
+// already included, is excluded once a matching negative sequence arrives.
+func TestNotNodePositiveThenBlockingNegative(t *testing.T) {
+	node, positive, negative := newNotNode()
+
+	positive.AddTuple(NewTuple(1))
+	if got := notNodeValues(node); len(got) != 1 {
+		t.Fatalf("want 1 included before any blocker, got %v", got)
+	}
+
+	negative.AddTuple(NewTuple(1))
+	if got := notNodeValues(node); len(got) != 0 {
+		t.Fatalf("want 0 included once a matching negative arrives, got %v", got)
+	}
+}
+
+// TestNotNodeNegativeThenPositive checks that a positive candidate arriving
+// after its blocker is already present is excluded from the start, rather
+// than briefly included.
+func TestNotNodeNegativeThenPositive(t *testing.T) {
+	node, positive, negative := newNotNode()
+
+	negative.AddTuple(NewTuple(1))
+	positive.AddTuple(NewTuple(1))
+
+	if got := notNodeValues(node); len(got) != 0 {
+		t.Fatalf("want 0 included when the blocker precedes the candidate, got %v", got)
+	}
+}
+
+// TestNotNodeRetractNegativeReincludes checks that retracting the only
+// blocker re-includes the candidate it was excluding.
+func TestNotNodeRetractNegativeReincludes(t *testing.T) {
+	node, positive, negative := newNotNode()
+
+	positive.AddTuple(NewTuple(1))
+	negative.AddTuple(NewTuple(1))
+	if got := notNodeValues(node); len(got) != 0 {
+		t.Fatalf("want 0 included while blocked, got %v", got)
+	}
+
+	negative.RemoveTuple(NewTuple(1))
+	if got := notNodeValues(node); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("want the candidate re-included once its only blocker is retracted, got %v", got)
+	}
+}
+
+// TestNotNodeMultipleBlockersRetraction checks that a candidate blocked by
+// two distinct negative sequences stays excluded until both are retracted,
+// not just the first. The two blockers are distinguished by a second
+// component the binding doesn't constrain, so they're distinct tuples that
+// both still block the same candidate.
+func TestNotNodeMultipleBlockersRetraction(t *testing.T) {
+	positive := NewAlphaNode[int]("positive/1")
+	negative := NewAlphaNode[int]("negative/2")
+	node := NewNotNode[int](positive, negative)
+	node.AddBinding(eqBinding)
+	positive.AddTarget(node, 0)
+	negative.AddTarget(node, 1)
+
+	positive.AddTuple(NewTuple(1))
+	negative.AddTuple(NewTuple(1, 100))
+	negative.AddTuple(NewTuple(1, 200))
+	if got := notNodeValues(node); len(got) != 0 {
+		t.Fatalf("want 0 included while two blockers are present, got %v", got)
+	}
+
+	negative.RemoveTuple(NewTuple(1, 100))
+	if got := notNodeValues(node); len(got) != 0 {
+		t.Fatalf("want still excluded after retracting only one of two blockers, got %v", got)
+	}
+
+	negative.RemoveTuple(NewTuple(1, 200))
+	if got := notNodeValues(node); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("want re-included once every blocker is retracted, got %v", got)
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNetworkString checks that String() actually renders into its return
+// value (rather than printing to stdout and returning an empty string).
+func TestNetworkString(t *testing.T) {
+	net := NewNetwork[string]()
+	net.AddTuple("parent", "alice", "bob")
+
+	got := net.String()
+	if !strings.Contains(got, "parent/2") {
+		t.Fatalf("want String() to mention the parent/2 signature, got %q", got)
+	}
+	if !strings.Contains(got, "alice") || !strings.Contains(got, "bob") {
+		t.Fatalf("want String() to include the tuple's components, got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matcher decides whether a single tuple component satisfies a pattern
+// position.
+type Matcher[T comparable] interface {
+	Match(value T) bool
+}
+
+type exactMatcher[T comparable] struct {
+	value T
+}
+
+// Exact matches a component that is equal to value.
+func Exact[T comparable](value T) Matcher[T] {
+	return exactMatcher[T]{value}
+}
+
+func (m exactMatcher[T]) Match(value T) bool {
+	return m.value == value
+}
+
+type anyMatcher[T comparable] struct{}
+
+// Any matches any component.
+func Any[T comparable]() Matcher[T] {
+	return anyMatcher[T]{}
+}
+
+func (anyMatcher[T]) Match(T) bool {
+	return true
+}
+
+type regexMatcher[T ~string] struct {
+	re *regexp.Regexp
+}
+
+// Regex matches a string-like component against a regular expression.
+func Regex[T ~string](pattern string) Matcher[T] {
+	return regexMatcher[T]{regexp.MustCompile(pattern)}
+}
+
+func (m regexMatcher[T]) Match(value T) bool {
+	return m.re.MatchString(string(value))
+}
+
+type globMatcher[T ~string] struct {
+	re *regexp.Regexp
+}
+
+// Glob matches a single tuple component against a glob pattern using the
+// `*`/`**`/`?` semantics popularized by gobwas/glob, with '/' as the
+// segment separator within that one component's string value: `?` matches
+// a single character other than '/', `*` matches within one segment, and `**` matches
+// across segments (i.e. it also matches '/'). This is entirely within the
+// one component Glob is applied to — it has no way to consume a
+// neighbouring tuple position, since Matcher.Match only ever sees one
+// component at a time (see accepts). A pattern that needs to match a
+// variable number of trailing positions isn't expressible via Matcher at
+// all; it would need a different per-tuple (not per-position) matching
+// primitive. This is a deliberate, final scope for Glob, not a pending
+// gap: a pattern node is already routed by a fixed functor/arity
+// signature (see Network.nodesForSignature), so every tuple it sees has
+// the same arity as the pattern — there is no variable-length tail left
+// for a single position to absorb.
+func Glob[T ~string](pattern string) Matcher[T] {
+	return globMatcher[T]{compileGlob(pattern)}
+}
+
+func (m globMatcher[T]) Match(value T) bool {
+	return m.re.MatchString(string(value))
+}
+
+func compileGlob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// NewAlphaPattern creates an alpha node that only accepts tuples matching
+// the given per-position matchers, rather than every tuple with the
+// signature sig. Multiple pattern nodes may share the same sig; the
+// network routes every incoming tuple with that functor/arity to each of
+// them in addition to the plain alpha node. Matchers that reduce to a
+// literal (e.g. Exact) still leave the node's ordinary indices populated,
+// so beta joins on that position stay O(1).
+func NewAlphaPattern[T comparable](sig string, matchers ...Matcher[T]) *AlphaNode[T] {
+	node := NewAlphaNode[T](sig)
+	node.pattern = matchers
+	return node
+}
+
+// accepts reports whether tuple satisfies node's pattern. A node with no
+// pattern (the common case) accepts every tuple with a matching signature.
+func (node *AlphaNode[T]) accepts(tuple *Tuple[T]) bool {
+	if node.pattern == nil {
+		return true
+	}
+	if len(node.pattern) != len(tuple.comps) {
+		return false
+	}
+	for i, m := range node.pattern {
+		if !m.Match(tuple.comps[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "testing"
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"user:*", "user:alice", true},
+		{"user:*", "user:alice/admin", false}, // * doesn't cross '/'
+		{"user:**", "user:alice/admin", true}, // ** does
+		{"user:?", "user:a", true},
+		{"user:?", "user:ab", false},
+		{"*", "anything", true},
+		{"*", "a/b", false},
+		{"**", "a/b/c", true},
+	}
+	for _, c := range cases {
+		m := Glob[string](c.pattern)
+		if got := m.Match(c.value); got != c.want {
+			t.Errorf("Glob(%q).Match(%q) = %v, want %v", c.pattern, c.value, got, c.want)
+		}
+	}
+}
+
+// TestGlobDoesNotSpanTuplePositions documents that Glob's `**` only spans
+// '/' within the one component it's applied to; a pattern node still
+// requires one matcher per tuple position and can't use a single glob to
+// absorb a variable-length tail of positions.
+func TestGlobDoesNotSpanTuplePositions(t *testing.T) {
+	node := NewAlphaPattern[string]("edge/2", Glob[string]("user:**"), Any[string]())
+	if node.accepts(NewTuple("user:alice/admin", "ignored")) != true {
+		t.Fatal("want a 2-component tuple matching (glob, any) to be accepted")
+	}
+	// A pattern with fewer matchers than the tuple's arity is rejected
+	// outright, rather than the last matcher absorbing the remaining
+	// positions.
+	short := NewAlphaPattern[string]("edge/2", Glob[string]("user:**"))
+	if short.accepts(NewTuple("user:alice/admin", "ignored")) {
+		t.Fatal("want a pattern with fewer matchers than the tuple's arity to reject rather than match the tail")
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+type rbColor int
+
+const (
+	rbRed rbColor = iota
+	rbBlack
+)
+
+type rbNode[K any, V any] struct {
+	color               rbColor
+	key                 K
+	values              []V
+	left, right, parent *rbNode[K, V]
+}
+
+// OrderedIndex is a red-black tree keyed by K, mapping each key to the
+// values filed under it (a slice, since a position in a tuple need not be
+// unique). It backs AlphaNode.AddOrderedIndex, letting BetaNode.Notify
+// resolve a Between binding by walking a subtree instead of scanning every
+// sequence.
+//
+// Range's callback runs while the traversal is in progress: inserting into
+// or deleting from the index from within it is not supported. Snapshot the
+// matching range into a slice first if the actions it drives might mutate
+// the index.
+type OrderedIndex[K any, V comparable] struct {
+	less func(a, b K) bool
+	nilN *rbNode[K, V]
+	root *rbNode[K, V]
+}
+
+func NewOrderedIndex[K any, V comparable](less func(a, b K) bool) *OrderedIndex[K, V] {
+	nilN := &rbNode[K, V]{color: rbBlack}
+	return &OrderedIndex[K, V]{less: less, nilN: nilN, root: nilN}
+}
+
+func (t *OrderedIndex[K, V]) find(key K) *rbNode[K, V] {
+	x := t.root
+	for x != t.nilN {
+		if t.less(key, x.key) {
+			x = x.left
+		} else if t.less(x.key, key) {
+			x = x.right
+		} else {
+			return x
+		}
+	}
+	return t.nilN
+}
+
+// Insert files value under key, appending to the existing bucket if key is
+// already present.
+func (t *OrderedIndex[K, V]) Insert(key K, value V) {
+	if n := t.find(key); n != t.nilN {
+		n.values = append(n.values, value)
+		return
+	}
+	z := &rbNode[K, V]{color: rbRed, key: key, left: t.nilN, right: t.nilN, parent: t.nilN, values: []V{value}}
+	y := t.nilN
+	x := t.root
+	for x != t.nilN {
+		y = x
+		if t.less(key, x.key) {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	z.parent = y
+	switch {
+	case y == t.nilN:
+		t.root = z
+	case t.less(key, y.key):
+		y.left = z
+	default:
+		y.right = z
+	}
+	t.insertFixup(z)
+}
+
+func (t *OrderedIndex[K, V]) insertFixup(z *rbNode[K, V]) {
+	for z.parent.color == rbRed {
+		if z.parent == z.parent.parent.left {
+			y := z.parent.parent.right
+			if y.color == rbRed {
+				z.parent.color = rbBlack
+				y.color = rbBlack
+				z.parent.parent.color = rbRed
+				z = z.parent.parent
+			} else {
+				if z == z.parent.right {
+					z = z.parent
+					t.leftRotate(z)
+				}
+				z.parent.color = rbBlack
+				z.parent.parent.color = rbRed
+				t.rightRotate(z.parent.parent)
+			}
+		} else {
+			y := z.parent.parent.left
+			if y.color == rbRed {
+				z.parent.color = rbBlack
+				y.color = rbBlack
+				z.parent.parent.color = rbRed
+				z = z.parent.parent
+			} else {
+				if z == z.parent.left {
+					z = z.parent
+					t.rightRotate(z)
+				}
+				z.parent.color = rbBlack
+				z.parent.parent.color = rbRed
+				t.leftRotate(z.parent.parent)
+			}
+		}
+	}
+	t.root.color = rbBlack
+}
+
+func (t *OrderedIndex[K, V]) leftRotate(x *rbNode[K, V]) {
+	y := x.right
+	x.right = y.left
+	if y.left != t.nilN {
+		y.left.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == t.nilN:
+		t.root = y
+	case x == x.parent.left:
+		x.parent.left = y
+	default:
+		x.parent.right = y
+	}
+	y.left = x
+	x.parent = y
+}
+
+func (t *OrderedIndex[K, V]) rightRotate(x *rbNode[K, V]) {
+	y := x.left
+	x.left = y.right
+	if y.right != t.nilN {
+		y.right.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == t.nilN:
+		t.root = y
+	case x == x.parent.right:
+		x.parent.right = y
+	default:
+		x.parent.left = y
+	}
+	y.right = x
+	x.parent = y
+}
+
+func (t *OrderedIndex[K, V]) transplant(u, v *rbNode[K, V]) {
+	switch {
+	case u.parent == t.nilN:
+		t.root = v
+	case u == u.parent.left:
+		u.parent.left = v
+	default:
+		u.parent.right = v
+	}
+	v.parent = u.parent
+}
+
+func (t *OrderedIndex[K, V]) minimum(x *rbNode[K, V]) *rbNode[K, V] {
+	for x.left != t.nilN {
+		x = x.left
+	}
+	return x
+}
+
+// Delete removes value from key's bucket, dropping key from the tree
+// entirely once its bucket is empty. It reports whether value was found.
+func (t *OrderedIndex[K, V]) Delete(key K, value V) bool {
+	n := t.find(key)
+	if n == t.nilN {
+		return false
+	}
+	pos := -1
+	for i, v := range n.values {
+		if v == value {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return false
+	}
+	n.values = append(n.values[:pos], n.values[pos+1:]...)
+	if len(n.values) > 0 {
+		return true
+	}
+	t.deleteNode(n)
+	return true
+}
+
+func (t *OrderedIndex[K, V]) deleteNode(z *rbNode[K, V]) {
+	y := z
+	yOriginalColor := y.color
+	var x *rbNode[K, V]
+	switch {
+	case z.left == t.nilN:
+		x = z.right
+		t.transplant(z, z.right)
+	case z.right == t.nilN:
+		x = z.left
+		t.transplant(z, z.left)
+	default:
+		y = t.minimum(z.right)
+		yOriginalColor = y.color
+		x = y.right
+		if y.parent == z {
+			x.parent = y
+		} else {
+			t.transplant(y, y.right)
+			y.right = z.right
+			y.right.parent = y
+		}
+		t.transplant(z, y)
+		y.left = z.left
+		y.left.parent = y
+		y.color = z.color
+	}
+	if yOriginalColor == rbBlack {
+		t.deleteFixup(x)
+	}
+}
+
+func (t *OrderedIndex[K, V]) deleteFixup(x *rbNode[K, V]) {
+	for x != t.root && x.color == rbBlack {
+		if x == x.parent.left {
+			w := x.parent.right
+			if w.color == rbRed {
+				w.color = rbBlack
+				x.parent.color = rbRed
+				t.leftRotate(x.parent)
+				w = x.parent.right
+			}
+			if w.left.color == rbBlack && w.right.color == rbBlack {
+				w.color = rbRed
+				x = x.parent
+			} else {
+				if w.right.color == rbBlack {
+					w.left.color = rbBlack
+					w.color = rbRed
+					t.rightRotate(w)
+					w = x.parent.right
+				}
+				w.color = x.parent.color
+				x.parent.color = rbBlack
+				w.right.color = rbBlack
+				t.leftRotate(x.parent)
+				x = t.root
+			}
+		} else {
+			w := x.parent.left
+			if w.color == rbRed {
+				w.color = rbBlack
+				x.parent.color = rbRed
+				t.rightRotate(x.parent)
+				w = x.parent.left
+			}
+			if w.right.color == rbBlack && w.left.color == rbBlack {
+				w.color = rbRed
+				x = x.parent
+			} else {
+				if w.left.color == rbBlack {
+					w.right.color = rbBlack
+					w.color = rbRed
+					t.leftRotate(w)
+					w = x.parent.left
+				}
+				w.color = x.parent.color
+				x.parent.color = rbBlack
+				w.left.color = rbBlack
+				t.rightRotate(x.parent)
+				x = t.root
+			}
+		}
+	}
+	x.color = rbBlack
+}
+
+// InOrder visits every (key, value) pair in ascending key order.
+func (t *OrderedIndex[K, V]) InOrder(cb func(key K, value V)) {
+	t.inOrder(t.root, cb)
+}
+
+func (t *OrderedIndex[K, V]) inOrder(n *rbNode[K, V], cb func(key K, value V)) {
+	if n == t.nilN {
+		return
+	}
+	t.inOrder(n.left, cb)
+	for _, v := range n.values {
+		cb(n.key, v)
+	}
+	t.inOrder(n.right, cb)
+}
+
+// Range visits every (key, value) pair with lo <= key <= hi, in ascending
+// key order, descending only into subtrees that can contain such a key.
+func (t *OrderedIndex[K, V]) Range(lo, hi K, cb func(key K, value V)) {
+	t.rangeNode(t.root, lo, hi, cb)
+}
+
+func (t *OrderedIndex[K, V]) rangeNode(n *rbNode[K, V], lo, hi K, cb func(key K, value V)) {
+	if n == t.nilN {
+		return
+	}
+	if t.less(lo, n.key) {
+		t.rangeNode(n.left, lo, hi, cb)
+	}
+	if !t.less(n.key, lo) && !t.less(hi, n.key) {
+		for _, v := range n.values {
+			cb(n.key, v)
+		}
+	}
+	if t.less(n.key, hi) {
+		t.rangeNode(n.right, lo, hi, cb)
+	}
+}
+
+// RangeFrom visits every (key, value) pair with key > lo (or key >= lo if
+// inclusive), in ascending key order, for an open-ended upper bound (Gt/Ge
+// bindings).
+func (t *OrderedIndex[K, V]) RangeFrom(lo K, inclusive bool, cb func(key K, value V)) {
+	t.rangeFromNode(t.root, lo, inclusive, cb)
+}
+
+func (t *OrderedIndex[K, V]) rangeFromNode(n *rbNode[K, V], lo K, inclusive bool, cb func(key K, value V)) {
+	if n == t.nilN {
+		return
+	}
+	if t.less(lo, n.key) {
+		t.rangeFromNode(n.left, lo, inclusive, cb)
+		for _, v := range n.values {
+			cb(n.key, v)
+		}
+		t.rangeFromNode(n.right, lo, inclusive, cb)
+		return
+	}
+	if inclusive && !t.less(n.key, lo) {
+		for _, v := range n.values {
+			cb(n.key, v)
+		}
+	}
+	// n.key <= lo, so the left subtree (all keys <= n.key) can't contain a
+	// match; only the right subtree might.
+	t.rangeFromNode(n.right, lo, inclusive, cb)
+}
+
+// RangeTo visits every (key, value) pair with key < hi (or key <= hi if
+// inclusive), in ascending key order, for an open-ended lower bound (Lt/Le
+// bindings).
+func (t *OrderedIndex[K, V]) RangeTo(hi K, inclusive bool, cb func(key K, value V)) {
+	t.rangeToNode(t.root, hi, inclusive, cb)
+}
+
+func (t *OrderedIndex[K, V]) rangeToNode(n *rbNode[K, V], hi K, inclusive bool, cb func(key K, value V)) {
+	if n == t.nilN {
+		return
+	}
+	if t.less(hi, n.key) {
+		// n.key > hi, so the right subtree (all keys >= n.key) can't
+		// contain a match; only the left subtree might.
+		t.rangeToNode(n.left, hi, inclusive, cb)
+		return
+	}
+	t.rangeToNode(n.left, hi, inclusive, cb)
+	if inclusive || t.less(n.key, hi) {
+		for _, v := range n.values {
+			cb(n.key, v)
+		}
+	}
+	t.rangeToNode(n.right, hi, inclusive, cb)
+}
@@ -7,21 +7,22 @@ package rete
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
-type Tuple struct {
-	comps []string
+type Tuple[T comparable] struct {
+	comps []T
 }
 
-func NewTuple(comps ...string) *Tuple {
-	return &Tuple{comps}
+func NewTuple[T comparable](comps ...T) *Tuple[T] {
+	return &Tuple[T]{comps}
 }
 
-func (t *Tuple) Get(i int) string {
+func (t *Tuple[T]) Get(i int) T {
 	return t.comps[i]
 }
 
-func (t *Tuple) Equals(t2 *Tuple) bool {
+func (t *Tuple[T]) Equals(t2 *Tuple[T]) bool {
 	if len(t.comps) == len(t2.comps) {
 		for i, c1 := range t.comps {
 			c2 := t2.comps[i]
@@ -34,28 +35,32 @@ func (t *Tuple) Equals(t2 *Tuple) bool {
 	return false
 }
 
-func (t *Tuple) String() string {
-	return "(" + strings.Join(t.comps, ",") + ")"
+func (t *Tuple[T]) String() string {
+	parts := make([]string, len(t.comps))
+	for i, c := range t.comps {
+		parts[i] = fmt.Sprint(c)
+	}
+	return "(" + strings.Join(parts, ",") + ")"
 }
 
-type Sequence struct {
-	tuples []*Tuple
+type Sequence[T comparable] struct {
+	tuples []*Tuple[T]
 }
 
-func NewSequence(tuples ...*Tuple) *Sequence {
-	return &Sequence{tuples}
+func NewSequence[T comparable](tuples ...*Tuple[T]) *Sequence[T] {
+	return &Sequence[T]{tuples}
 }
 
-func (seq *Sequence) Appending(seq2 *Sequence) *Sequence {
+func (seq *Sequence[T]) Appending(seq2 *Sequence[T]) *Sequence[T] {
 	t1 := seq.tuples[0:len(seq.tuples):len(seq.tuples)]
-	return &Sequence{append(t1, seq2.tuples...)}
+	return &Sequence[T]{append(t1, seq2.tuples...)}
 }
 
-func (seq *Sequence) Get(i int) *Tuple {
+func (seq *Sequence[T]) Get(i int) *Tuple[T] {
 	return seq.tuples[i]
 }
 
-func (seq1 *Sequence) Equals(seq2 *Sequence) bool {
+func (seq1 *Sequence[T]) Equals(seq2 *Sequence[T]) bool {
 	if len(seq1.tuples) == len(seq2.tuples) {
 		for i, t1 := range seq1.tuples {
 			t2 := seq2.tuples[i]
@@ -68,7 +73,7 @@ func (seq1 *Sequence) Equals(seq2 *Sequence) bool {
 	return false
 }
 
-func (seq *Sequence) String() string {
+func (seq *Sequence[T]) String() string {
 	b := strings.Builder{}
 	b.WriteString("[")
 	for i, t := range seq.tuples {
@@ -81,147 +86,586 @@ func (seq *Sequence) String() string {
 	return b.String()
 }
 
-type Node interface {
-	EnumSequences(cb func(*Sequence))
+type Node[T comparable] interface {
+	EnumSequences(cb func(*Sequence[T]))
+}
+
+// Notifiable is implemented by every node that can sit downstream of an
+// alpha or beta node and react to sequences pushed from one of its
+// sources, identified by index. BetaNode, NotNode, and AggregateNode all
+// implement it, which is what lets AddTarget wire any of them together.
+type Notifiable[T comparable] interface {
+	Notify(index int, seq *Sequence[T])
+	Retract(index int, seq *Sequence[T])
 }
 
-type alphaIndexKey struct {
+type alphaIndexKey[T comparable] struct {
 	pos   int
-	value string
+	value T
 }
 
-type targetNode struct {
-	node  *BetaNode
+type targetNode[T comparable] struct {
+	node  Notifiable[T]
 	index int
 }
 
-type AlphaNode struct {
-	sig     string
-	tuples  []*Tuple
-	indices map[alphaIndexKey][]*Tuple
-	targets []targetNode
-	actions []func(*Sequence)
+// AlphaNode's tuples, indices, orderedIndices, targets, and action lists
+// are guarded by mu so it can be shared across goroutines, e.g. when fed
+// through a SyncNetwork. Propagation to targets and actions always happens
+// after mu is released, so a downstream callback that loops back into this
+// node (directly or through another node) cannot deadlock against it.
+type AlphaNode[T comparable] struct {
+	mu             sync.RWMutex
+	sig            string
+	pattern        []Matcher[T]
+	tuples         []*Tuple[T]
+	indices        map[alphaIndexKey[T]][]*Tuple[T]
+	orderedIndices map[int]*OrderedIndex[T, *Tuple[T]]
+	targets        []targetNode[T]
+	actions        []func(*Sequence[T])
+	retractActions []func(*Sequence[T])
 }
 
-func NewAlphaNode(sig string) *AlphaNode {
-	return &AlphaNode{sig, nil, make(map[alphaIndexKey][]*Tuple), nil, nil}
+func NewAlphaNode[T comparable](sig string) *AlphaNode[T] {
+	return &AlphaNode[T]{sig: sig, indices: make(map[alphaIndexKey[T]][]*Tuple[T])}
 }
 
-func (node *AlphaNode) AddAction(a func(*Sequence)) {
+func (node *AlphaNode[T]) AddAction(a func(*Sequence[T])) {
+	node.mu.Lock()
 	node.actions = append(node.actions, a)
+	node.mu.Unlock()
+}
+
+// AddRetractAction registers a callback invoked whenever a tuple previously
+// added to this node is removed via RemoveTuple.
+func (node *AlphaNode[T]) AddRetractAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.retractActions = append(node.retractActions, a)
+	node.mu.Unlock()
 }
 
-func (node *AlphaNode) EnumSequences(cb func(*Sequence)) {
-	for _, t := range node.tuples {
+func (node *AlphaNode[T]) EnumSequences(cb func(*Sequence[T])) {
+	node.mu.RLock()
+	tuples := make([]*Tuple[T], len(node.tuples))
+	copy(tuples, node.tuples)
+	node.mu.RUnlock()
+	for _, t := range tuples {
 		cb(NewSequence(t))
 	}
 }
 
-func (node *AlphaNode) AddTuple(tuple *Tuple) bool {
-	key := alphaIndexKey{0, tuple.comps[0]}
+func (node *AlphaNode[T]) AddTuple(tuple *Tuple[T]) bool {
+	node.mu.Lock()
+	if !node.accepts(tuple) {
+		node.mu.Unlock()
+		return false
+	}
+	key := alphaIndexKey[T]{0, tuple.comps[0]}
 	if index, ok := node.indices[key]; ok {
 		for _, tuple2 := range index {
 			if tuple.Equals(tuple2) {
+				node.mu.Unlock()
 				return false
 			}
 		}
 	}
 	node.tuples = append(node.tuples, tuple)
 	for i, comp := range tuple.comps {
-		key := alphaIndexKey{i, comp}
+		key := alphaIndexKey[T]{i, comp}
 		index := node.indices[key]
 		index = append(index, tuple)
 		node.indices[key] = index
 	}
+	for pos, idx := range node.orderedIndices {
+		idx.Insert(tuple.comps[pos], tuple)
+	}
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	actions := make([]func(*Sequence[T]), len(node.actions))
+	copy(actions, node.actions)
+	node.mu.Unlock()
+
 	seq := NewSequence(tuple)
-	for _, t := range node.targets {
+	for _, t := range targets {
 		t.node.Notify(t.index, seq)
 	}
-	for _, a := range node.actions {
+	for _, a := range actions {
+		a(seq)
+	}
+	return true
+}
+
+// RemoveTuple retracts a tuple previously accepted by AddTuple. It removes
+// the tuple from the node and from every index bucket it was filed under,
+// propagates the retraction to every target beta node, and invokes the
+// retract actions. It reports whether the tuple was present.
+func (node *AlphaNode[T]) RemoveTuple(tuple *Tuple[T]) bool {
+	node.mu.Lock()
+	pos := -1
+	for i, t := range node.tuples {
+		if t.Equals(tuple) {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		node.mu.Unlock()
+		return false
+	}
+	removed := node.tuples[pos]
+	node.tuples = append(node.tuples[:pos], node.tuples[pos+1:]...)
+	for i, comp := range removed.comps {
+		key := alphaIndexKey[T]{i, comp}
+		index := node.indices[key]
+		for j, t := range index {
+			if t.Equals(removed) {
+				index = append(index[:j], index[j+1:]...)
+				break
+			}
+		}
+		if len(index) == 0 {
+			delete(node.indices, key)
+		} else {
+			node.indices[key] = index
+		}
+	}
+	for pos, idx := range node.orderedIndices {
+		idx.Delete(removed.comps[pos], removed)
+	}
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	retractActions := make([]func(*Sequence[T]), len(node.retractActions))
+	copy(retractActions, node.retractActions)
+	node.mu.Unlock()
+
+	seq := NewSequence(removed)
+	for _, t := range targets {
+		t.node.Retract(t.index, seq)
+	}
+	for _, a := range retractActions {
 		a(seq)
 	}
 	return true
 }
 
-func (node *AlphaNode) AddTarget(t *BetaNode, index int) {
-	node.targets = append(node.targets, targetNode{t, index})
+func (node *AlphaNode[T]) AddTarget(t Notifiable[T], index int) {
+	node.mu.Lock()
+	node.targets = append(node.targets, targetNode[T]{t, index})
+	node.mu.Unlock()
 }
 
-type betaIndexKey struct {
+type betaIndexKey[T comparable] struct {
 	pos1  int
 	pos2  int
-	value string
+	value T
 }
 
-type Binding struct {
+// Op selects the comparison a Binding checks between two positions. Eq,
+// the zero value, is the original equality join.
+type Op int
+
+const (
+	Eq Op = iota
+	Lt
+	Le
+	Gt
+	Ge
+	Between
+)
+
+// Binding constrains a beta join between a position in one side's sequence
+// (Tuple1/Comp1) and a position in the other's (Tuple2/Comp2). Op selects
+// the comparison; for anything but Eq, Less must be supplied and defines
+// the ordering used to compare the two values. Between additionally reads
+// an upper bound from Tuple3/Comp3 on the same side as Tuple2/Comp2, and
+// holds iff Tuple1/Comp1's value is between the two, inclusive.
+type Binding[T comparable] struct {
 	Tuple1 int
 	Comp1  int
 	Tuple2 int
 	Comp2  int
+	Tuple3 int
+	Comp3  int
+	Op     Op
+	Less   func(a, b T) bool
+}
+
+// provenance records one pair of source sequences a derived sequence was
+// joined from. A derived sequence can have more than one provenance entry
+// if more than one pair of source sequences joins to the same result; it
+// is only retracted once every one of its justifications is gone.
+type provenance[T comparable] struct {
+	left  *Sequence[T]
+	right *Sequence[T]
+}
+
+// betaOrderedIndexKey identifies the (tuple, comp) position within a
+// BetaNode's derived sequences that one of its ordered indices is built
+// on, mirroring how Binding's Tuple/Comp fields address a position.
+type betaOrderedIndexKey struct {
+	tuple int
+	comp  int
+}
+
+// BetaNode's sequences, indices, orderedIndices, provenance, targets, and
+// action lists are guarded by mu, on the same terms as AlphaNode:
+// propagation to targets and actions always happens after mu is released.
+type BetaNode[T comparable] struct {
+	mu             sync.RWMutex
+	sources        []Node[T]
+	actions        []func(*Sequence[T])
+	retractActions []func(*Sequence[T])
+	sequences      []*Sequence[T]
+	indices        map[betaIndexKey[T]][]*Sequence[T]
+	orderedIndices map[betaOrderedIndexKey]*OrderedIndex[T, *Sequence[T]]
+	provenance     map[*Sequence[T]][]provenance[T]
+	bindings       []Binding[T]
+	targets        []targetNode[T]
+}
+
+func NewBetaNode[T comparable]() *BetaNode[T] {
+	return &BetaNode[T]{
+		indices:    make(map[betaIndexKey[T]][]*Sequence[T]),
+		provenance: make(map[*Sequence[T]][]provenance[T]),
+	}
+}
+
+func (node *BetaNode[T]) AddBinding(b Binding[T]) {
+	node.mu.Lock()
+	node.bindings = append(node.bindings, b)
+	node.mu.Unlock()
+}
+
+// AddOrderedIndex builds a red-black tree index on the component at
+// (tuple, comp) within this node's derived sequences, ordered by less, and
+// keeps it up to date as sequences are added and removed. It lets a
+// downstream BetaNode's Notify resolve a Between/Lt/Le/Gt/Ge binding
+// against this position in O(log n + k) instead of scanning every
+// sequence, the same way AlphaNode.AddOrderedIndex does for single
+// tuples.
+func (node *BetaNode[T]) AddOrderedIndex(tuple, comp int, less func(a, b T) bool) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if node.orderedIndices == nil {
+		node.orderedIndices = make(map[betaOrderedIndexKey]*OrderedIndex[T, *Sequence[T]])
+	}
+	idx := NewOrderedIndex[T, *Sequence[T]](less)
+	for _, seq := range node.sequences {
+		idx.Insert(seq.Get(tuple).Get(comp), seq)
+	}
+	node.orderedIndices[betaOrderedIndexKey{tuple, comp}] = idx
+}
+
+// EnumRange enumerates every derived sequence whose component at
+// (tupleIdx, compIdx) falls in [lo, hi], in ascending order of that
+// component. If that position has no ordered index, it falls back to
+// enumerating every sequence in the node; the caller re-checks the
+// binding against each candidate, so a broader candidate set is just a
+// missed optimization, not incorrect.
+func (node *BetaNode[T]) EnumRange(tupleIdx, compIdx int, lo, hi T, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[betaOrderedIndexKey{tupleIdx, compIdx}]
+	if !ok {
+		sequences := make([]*Sequence[T], len(node.sequences))
+		copy(sequences, node.sequences)
+		node.mu.RUnlock()
+		for _, s := range sequences {
+			cb(s)
+		}
+		return
+	}
+	var matches []*Sequence[T]
+	idx.Range(lo, hi, func(_ T, seq *Sequence[T]) {
+		matches = append(matches, seq)
+	})
+	node.mu.RUnlock()
+	for _, s := range matches {
+		cb(s)
+	}
+}
+
+// EnumGreater enumerates every derived sequence whose component at
+// (tupleIdx, compIdx) is greater than bound (or, if inclusive, greater
+// than or equal to it), in ascending order of that component. It
+// otherwise behaves like EnumRange, including the full-scan fallback.
+func (node *BetaNode[T]) EnumGreater(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[betaOrderedIndexKey{tupleIdx, compIdx}]
+	if !ok {
+		sequences := make([]*Sequence[T], len(node.sequences))
+		copy(sequences, node.sequences)
+		node.mu.RUnlock()
+		for _, s := range sequences {
+			cb(s)
+		}
+		return
+	}
+	var matches []*Sequence[T]
+	idx.RangeFrom(bound, inclusive, func(_ T, seq *Sequence[T]) {
+		matches = append(matches, seq)
+	})
+	node.mu.RUnlock()
+	for _, s := range matches {
+		cb(s)
+	}
 }
 
-type BetaNode struct {
-	sources   []Node
-	actions   []func(*Sequence)
-	sequences []*Sequence
-	indices   map[betaIndexKey][]*Sequence
-	bindings  []Binding
-	targets   []targetNode
+// EnumLess enumerates every derived sequence whose component at
+// (tupleIdx, compIdx) is less than bound (or, if inclusive, less than or
+// equal to it), in ascending order of that component. It otherwise
+// behaves like EnumRange, including the full-scan fallback.
+func (node *BetaNode[T]) EnumLess(tupleIdx, compIdx int, bound T, inclusive bool, cb func(*Sequence[T])) {
+	node.mu.RLock()
+	idx, ok := node.orderedIndices[betaOrderedIndexKey{tupleIdx, compIdx}]
+	if !ok {
+		sequences := make([]*Sequence[T], len(node.sequences))
+		copy(sequences, node.sequences)
+		node.mu.RUnlock()
+		for _, s := range sequences {
+			cb(s)
+		}
+		return
+	}
+	var matches []*Sequence[T]
+	idx.RangeTo(bound, inclusive, func(_ T, seq *Sequence[T]) {
+		matches = append(matches, seq)
+	})
+	node.mu.RUnlock()
+	for _, s := range matches {
+		cb(s)
+	}
 }
 
-func NewBetaNode() *BetaNode {
-	return &BetaNode{indices: make(map[betaIndexKey][]*Sequence)}
+func (node *BetaNode[T]) AddTarget(t Notifiable[T], index int) {
+	node.mu.Lock()
+	node.targets = append(node.targets, targetNode[T]{t, index})
+	node.mu.Unlock()
 }
 
-func (node *BetaNode) AddBinding(b Binding) {
-	node.bindings = append(node.bindings, b)
+func (node *BetaNode[T]) AddSequence(seq *Sequence[T]) bool {
+	node.mu.Lock()
+	_, inserted := node.insertLocked(seq)
+	if !inserted {
+		node.mu.Unlock()
+		return false
+	}
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	node.mu.Unlock()
+
+	for _, t := range targets {
+		t.node.Notify(t.index, seq)
+	}
+	return true
 }
 
-func (node *BetaNode) AddTarget(t *BetaNode, index int) {
-	node.targets = append(node.targets, targetNode{t, index})
+// addSequenceWithProvenance is AddSequence plus recording prov, under the
+// same lock acquisition used for the insert so a concurrent Retract can
+// never observe the sequence inserted but its provenance not yet recorded
+// (which would make the retraction silently miss it).
+//
+// If seq is already present (another pair of source sequences already
+// derived the same result), prov is appended as an additional
+// justification for the existing sequence rather than replacing its
+// provenance, so Retract only drops the result once every justification
+// supporting it is gone. It reports whether seq was newly inserted, i.e.
+// whether targets/actions should fire for it.
+func (node *BetaNode[T]) addSequenceWithProvenance(seq *Sequence[T], prov provenance[T]) bool {
+	node.mu.Lock()
+	existing, inserted := node.insertLocked(seq)
+	node.provenance[existing] = append(node.provenance[existing], prov)
+	if !inserted {
+		node.mu.Unlock()
+		return false
+	}
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	node.mu.Unlock()
+
+	for _, t := range targets {
+		t.node.Notify(t.index, seq)
+	}
+	return true
 }
 
-func (node *BetaNode) AddSequence(seq *Sequence) bool {
-	key := betaIndexKey{0, 0, seq.tuples[0].comps[0]}
+// insertLocked adds seq to the node's sequences and indices unless an
+// equal sequence is already present, in which case it leaves the node
+// unchanged. It returns the sequence now on file for seq's value (seq
+// itself if freshly inserted, the previously-stored one otherwise) and
+// whether it was newly inserted. Callers must hold node.mu.
+func (node *BetaNode[T]) insertLocked(seq *Sequence[T]) (onFile *Sequence[T], inserted bool) {
+	key := betaIndexKey[T]{0, 0, seq.tuples[0].comps[0]}
 	if index, ok := node.indices[key]; ok {
 		for _, seq2 := range index {
 			if seq.Equals(seq2) {
-				return false
+				return seq2, false
 			}
 		}
 	}
 	node.sequences = append(node.sequences, seq)
 	for i, tuple := range seq.tuples {
 		for j, comp := range tuple.comps {
-			key := betaIndexKey{i, j, comp}
+			key := betaIndexKey[T]{i, j, comp}
 			index := node.indices[key]
 			index = append(index, seq)
 			node.indices[key] = index
 		}
 	}
-	for _, t := range node.targets {
-		t.node.Notify(t.index, seq)
+	for key, idx := range node.orderedIndices {
+		idx.Insert(seq.Get(key.tuple).Get(key.comp), seq)
+	}
+	return seq, true
+}
+
+// RemoveSequence retracts a sequence previously accepted by AddSequence. It
+// removes the sequence from the node and from every index bucket it was
+// filed under, drops its own provenance record, propagates the retraction
+// to every target node (which removes what they derived from it), and invokes the
+// retract actions. It reports whether the sequence was present.
+func (node *BetaNode[T]) RemoveSequence(seq *Sequence[T]) bool {
+	node.mu.Lock()
+	pos := -1
+	for i, s := range node.sequences {
+		if s.Equals(seq) {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		node.mu.Unlock()
+		return false
+	}
+	removed := node.sequences[pos]
+	node.sequences = append(node.sequences[:pos], node.sequences[pos+1:]...)
+	for i, tuple := range removed.tuples {
+		for j, comp := range tuple.comps {
+			key := betaIndexKey[T]{i, j, comp}
+			index := node.indices[key]
+			for k, s := range index {
+				if s.Equals(removed) {
+					index = append(index[:k], index[k+1:]...)
+					break
+				}
+			}
+			if len(index) == 0 {
+				delete(node.indices, key)
+			} else {
+				node.indices[key] = index
+			}
+		}
+	}
+	for key, idx := range node.orderedIndices {
+		idx.Delete(removed.Get(key.tuple).Get(key.comp), removed)
+	}
+	delete(node.provenance, removed)
+	targets := make([]targetNode[T], len(node.targets))
+	copy(targets, node.targets)
+	retractActions := make([]func(*Sequence[T]), len(node.retractActions))
+	copy(retractActions, node.retractActions)
+	node.mu.Unlock()
+
+	for _, t := range targets {
+		t.node.Retract(t.index, removed)
+	}
+	for _, a := range retractActions {
+		a(removed)
 	}
 	return true
 }
 
-func (node *BetaNode) AddSource(n Node) {
+func (node *BetaNode[T]) AddSource(n Node[T]) {
+	node.mu.Lock()
 	node.sources = append(node.sources, n)
+	node.mu.Unlock()
 }
 
-func (node *BetaNode) AddAction(a func(*Sequence)) {
+func (node *BetaNode[T]) AddAction(a func(*Sequence[T])) {
+	node.mu.Lock()
 	node.actions = append(node.actions, a)
+	node.mu.Unlock()
+}
+
+// AddRetractAction registers a callback invoked whenever a sequence
+// previously added to this node is removed, either directly via
+// RemoveSequence or transitively via Retract.
+func (node *BetaNode[T]) AddRetractAction(a func(*Sequence[T])) {
+	node.mu.Lock()
+	node.retractActions = append(node.retractActions, a)
+	node.mu.Unlock()
+}
+
+func checkBinding[T comparable](seq1, seq2 *Sequence[T], b Binding[T]) bool {
+	v1 := seq1.Get(b.Tuple1).Get(b.Comp1)
+	v2 := seq2.Get(b.Tuple2).Get(b.Comp2)
+	switch b.Op {
+	case Lt:
+		return b.Less(v1, v2)
+	case Le:
+		return !b.Less(v2, v1)
+	case Gt:
+		return b.Less(v2, v1)
+	case Ge:
+		return !b.Less(v1, v2)
+	case Between:
+		v3 := seq2.Get(b.Tuple3).Get(b.Comp3)
+		return !b.Less(v1, v2) && !b.Less(v3, v1)
+	default:
+		return v1 == v2
+	}
 }
 
-func checkBinding(seq1, seq2 *Sequence, b Binding) bool {
-	return seq1.Get(b.Tuple1).Get(b.Comp1) == seq2.Get(b.Tuple2).Get(b.Comp2)
-}
-
-func (node *BetaNode) Notify(index int, seq *Sequence) {
-	node2 := node.sources[1-index]
-	node2.EnumSequences(func(seq2 *Sequence) {
+// findRangeBinding looks for the first binding against the newly-arrived
+// sequence whose Op can be resolved against the other side's ordered
+// index (Between, Lt, Le, Gt, or Ge) instead of a full scan, and returns a
+// closure that walks re for it. Eq bindings have no ordering to index on,
+// so they're left for enumerate's full scan, same as a binding whose
+// position has no ordered index at all; either way Notify re-checks every
+// binding against each candidate, so this is purely a performance choice.
+func findRangeBinding[T comparable](bindings []Binding[T], seq *Sequence[T]) (enumerate func(re RangeEnumerable[T], cb func(*Sequence[T])), ok bool) {
+	for _, b := range bindings {
+		switch b.Op {
+		case Between:
+			lo, hi := seq.Get(b.Tuple2).Get(b.Comp2), seq.Get(b.Tuple3).Get(b.Comp3)
+			return func(re RangeEnumerable[T], cb func(*Sequence[T])) {
+				re.EnumRange(b.Tuple1, b.Comp1, lo, hi, cb)
+			}, true
+		case Gt, Ge:
+			bound := seq.Get(b.Tuple2).Get(b.Comp2)
+			inclusive := b.Op == Ge
+			return func(re RangeEnumerable[T], cb func(*Sequence[T])) {
+				re.EnumGreater(b.Tuple1, b.Comp1, bound, inclusive, cb)
+			}, true
+		case Lt, Le:
+			bound := seq.Get(b.Tuple2).Get(b.Comp2)
+			inclusive := b.Op == Le
+			return func(re RangeEnumerable[T], cb func(*Sequence[T])) {
+				re.EnumLess(b.Tuple1, b.Comp1, bound, inclusive, cb)
+			}, true
+		}
+	}
+	return nil, false
+}
+
+func (node *BetaNode[T]) Notify(index int, seq *Sequence[T]) {
+	node.mu.RLock()
+	sources := make([]Node[T], len(node.sources))
+	copy(sources, node.sources)
+	bindings := make([]Binding[T], len(node.bindings))
+	copy(bindings, node.bindings)
+	node.mu.RUnlock()
+
+	node2 := sources[1-index]
+	enumerate := node2.EnumSequences
+	if index == 1 {
+		if re, ok := node2.(RangeEnumerable[T]); ok {
+			if rangeEnum, ok := findRangeBinding(bindings, seq); ok {
+				enumerate = func(cb func(*Sequence[T])) { rangeEnum(re, cb) }
+			}
+		}
+	}
+	enumerate(func(seq2 *Sequence[T]) {
 		cons := true
-		for _, b := range node.bindings {
+		for _, b := range bindings {
 			var cons2 bool
 			if index == 0 {
 				cons2 = checkBinding(seq, seq2, b)
@@ -234,72 +678,189 @@ func (node *BetaNode) Notify(index int, seq *Sequence) {
 			}
 		}
 		if cons {
-			var newSeq *Sequence
+			var newSeq *Sequence[T]
 			if index == 0 {
 				newSeq = seq.Appending(seq2)
 			} else {
 				newSeq = seq2.Appending(seq)
 			}
-			if node.AddSequence(newSeq) {
+			var prov provenance[T]
+			if index == 0 {
+				prov = provenance[T]{seq, seq2}
+			} else {
+				prov = provenance[T]{seq2, seq}
+			}
+			if node.addSequenceWithProvenance(newSeq, prov) {
 				node.performActions(newSeq)
 			}
 		}
 	})
 }
 
-func (node *BetaNode) performActions(seq *Sequence) {
-	for _, a := range node.actions {
+// Retract mirrors Notify: it drops every justification recorded for this
+// node's results that depended on seq (arriving on source index), and
+// removes a result entirely only once none of its justifications remain.
+// A result derived from more than one pair of source sequences survives
+// the retraction of just one of those pairs, and is retracted in turn
+// only when the last one goes.
+func (node *BetaNode[T]) Retract(index int, seq *Sequence[T]) {
+	node.mu.Lock()
+	var dead []*Sequence[T]
+	for s, provs := range node.provenance {
+		remaining := provs[:0:0]
+		for _, p := range provs {
+			var parent *Sequence[T]
+			if index == 0 {
+				parent = p.left
+			} else {
+				parent = p.right
+			}
+			if !parent.Equals(seq) {
+				remaining = append(remaining, p)
+			}
+		}
+		if len(remaining) == 0 {
+			dead = append(dead, s)
+		} else if len(remaining) != len(provs) {
+			node.provenance[s] = remaining
+		}
+	}
+	node.mu.Unlock()
+
+	for _, s := range dead {
+		node.RemoveSequence(s)
+	}
+}
+
+func (node *BetaNode[T]) performActions(seq *Sequence[T]) {
+	node.mu.RLock()
+	actions := make([]func(*Sequence[T]), len(node.actions))
+	copy(actions, node.actions)
+	node.mu.RUnlock()
+	for _, a := range actions {
 		a(seq)
 	}
 }
 
-func (node *BetaNode) EnumSequences(cb func(*Sequence)) {
-	for _, s := range node.sequences {
+func (node *BetaNode[T]) EnumSequences(cb func(*Sequence[T])) {
+	node.mu.RLock()
+	sequences := make([]*Sequence[T], len(node.sequences))
+	copy(sequences, node.sequences)
+	node.mu.RUnlock()
+	for _, s := range sequences {
 		cb(s)
 	}
 }
 
-type Network struct {
-	alphaNodes map[string]*AlphaNode
+// Network's registries are guarded by mu so AddTuple/RemoveTuple/AddNode
+// can be called concurrently; see SyncNetwork for a variant that also
+// dispatches join propagation onto a worker pool.
+type Network[T comparable] struct {
+	mu           sync.RWMutex
+	alphaNodes   map[string]*AlphaNode[T]
+	patternNodes map[string][]*AlphaNode[T]
 }
 
-func NewNetwork() *Network {
-	return &Network{make(map[string]*AlphaNode)}
+func NewNetwork[T comparable]() *Network[T] {
+	return &Network[T]{
+		alphaNodes:   make(map[string]*AlphaNode[T]),
+		patternNodes: make(map[string][]*AlphaNode[T]),
+	}
 }
 
-func (net *Network) AlphaNode(sig string) *AlphaNode {
+func (net *Network[T]) AlphaNode(sig string) *AlphaNode[T] {
+	net.mu.Lock()
+	defer net.mu.Unlock()
 	node, ok := net.alphaNodes[sig]
 	if !ok {
-		node = NewAlphaNode(sig)
+		node = NewAlphaNode[T](sig)
 		net.alphaNodes[sig] = node
 	}
 	return node
 }
 
-func (net *Network) AddNode(node Node) {
+func (net *Network[T]) AddNode(node Node[T]) {
+	net.mu.Lock()
+	defer net.mu.Unlock()
 	switch node := node.(type) {
-	case *AlphaNode:
-		net.alphaNodes[node.sig] = node
+	case *AlphaNode[T]:
+		if node.pattern != nil {
+			net.patternNodes[node.sig] = append(net.patternNodes[node.sig], node)
+		} else {
+			net.alphaNodes[node.sig] = node
+		}
 	}
 }
 
-func (net *Network) AddTuple(functor string, comps ...string) {
-	sig := fmt.Sprintf("%s/%d", functor, len(comps))
+// nodesForSignature looks up the plain alpha node and pattern nodes for sig,
+// creating the plain node on first use. The common case (the node already
+// exists) only takes a read lock, so AddTuple calls for different
+// signatures don't serialize against each other; only the first AddTuple
+// for a given signature pays for an exclusive lock.
+func (net *Network[T]) nodesForSignature(sig string) (*AlphaNode[T], []*AlphaNode[T]) {
+	net.mu.RLock()
 	node, ok := net.alphaNodes[sig]
+	if ok {
+		patterns := make([]*AlphaNode[T], len(net.patternNodes[sig]))
+		copy(patterns, net.patternNodes[sig])
+		net.mu.RUnlock()
+		return node, patterns
+	}
+	net.mu.RUnlock()
+
+	net.mu.Lock()
+	node, ok = net.alphaNodes[sig]
 	if !ok {
-		node = NewAlphaNode(sig)
+		node = NewAlphaNode[T](sig)
 		net.alphaNodes[sig] = node
 	}
+	patterns := make([]*AlphaNode[T], len(net.patternNodes[sig]))
+	copy(patterns, net.patternNodes[sig])
+	net.mu.Unlock()
+	return node, patterns
+}
+
+func (net *Network[T]) AddTuple(functor string, comps ...T) {
+	sig := fmt.Sprintf("%s/%d", functor, len(comps))
+	node, patterns := net.nodesForSignature(sig)
 	tuple := NewTuple(comps...)
 	node.AddTuple(tuple)
+	for _, pnode := range patterns {
+		pnode.AddTuple(tuple)
+	}
+}
+
+// RemoveTuple retracts a tuple previously added via AddTuple, propagating
+// the retraction through the network, including to any pattern nodes
+// sharing the same functor/arity. It reports whether the tuple was present
+// in the plain alpha node for that signature.
+func (net *Network[T]) RemoveTuple(functor string, comps ...T) bool {
+	sig := fmt.Sprintf("%s/%d", functor, len(comps))
+	net.mu.RLock()
+	node, ok := net.alphaNodes[sig]
+	patterns := make([]*AlphaNode[T], len(net.patternNodes[sig]))
+	copy(patterns, net.patternNodes[sig])
+	net.mu.RUnlock()
+
+	tuple := NewTuple(comps...)
+	for _, pnode := range patterns {
+		pnode.RemoveTuple(tuple)
+	}
+	if !ok {
+		return false
+	}
+	return node.RemoveTuple(tuple)
 }
 
-func (net *Network) String() string {
+func (net *Network[T]) String() string {
+	net.mu.RLock()
+	defer net.mu.RUnlock()
 	sb := strings.Builder{}
 	for sig, node := range net.alphaNodes {
-		fmt.Println(sig)
+		sb.WriteString(sig)
+		sb.WriteString("\n")
 		for _, tuple := range node.tuples {
-			fmt.Printf(" %s\n", tuple)
+			fmt.Fprintf(&sb, " %s\n", tuple)
 		}
 	}
 	return sb.String()
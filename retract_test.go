@@ -0,0 +1,96 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+package rete
+
+import "testing"
+
+// TestNetworkRetraction builds grandparent(X,Z) :- parent(X,Y), parent(Y,Z)
+// over a self-joined parent/2 alpha node, and checks that retracting a base
+// fact propagates through the beta join and removes the derived fact.
+func TestNetworkRetraction(t *testing.T) {
+	net := NewNetwork[string]()
+	parent := net.AlphaNode("parent/2")
+	grand := net.AlphaNode("grandparent/2")
+
+	join := NewBetaNode[string]()
+	join.AddSource(parent)
+	join.AddSource(parent)
+	join.AddBinding(Binding[string]{Tuple1: 0, Comp1: 1, Tuple2: 0, Comp2: 0})
+	parent.AddTarget(join, 0)
+	parent.AddTarget(join, 1)
+	join.AddAction(func(seq *Sequence[string]) {
+		net.AddTuple("grandparent", seq.Get(0).Get(0), seq.Get(1).Get(1))
+	})
+	join.AddRetractAction(func(seq *Sequence[string]) {
+		net.RemoveTuple("grandparent", seq.Get(0).Get(0), seq.Get(1).Get(1))
+	})
+
+	net.AddTuple("parent", "alice", "bob")
+	net.AddTuple("parent", "bob", "carol")
+
+	var before []string
+	grand.EnumSequences(func(seq *Sequence[string]) { before = append(before, seq.Get(0).String()) })
+	if len(before) != 1 || before[0] != "(alice,carol)" {
+		t.Fatalf("want [(alice,carol)] after seeding facts, got %v", before)
+	}
+
+	if !net.RemoveTuple("parent", "alice", "bob") {
+		t.Fatal("expected parent(alice,bob) to be present for retraction")
+	}
+
+	var after []string
+	grand.EnumSequences(func(seq *Sequence[string]) { after = append(after, seq.Get(0).String()) })
+	if len(after) != 0 {
+		t.Fatalf("want no grandparent facts once a supporting parent fact is retracted, got %v", after)
+	}
+}
+
+// TestBetaNodeMultiJustificationRetract exercises a derived sequence that
+// is justified by two different pairs of source sequences (a "diamond" in
+// the dependency graph). Retracting only one of the two justifications
+// must not remove the result; only retracting the last one should.
+func TestBetaNodeMultiJustificationRetract(t *testing.T) {
+	node := NewBetaNode[int]()
+
+	seqA := NewSequence(NewTuple(1))
+	seqB1 := NewSequence(NewTuple(2))
+	seqB2 := NewSequence(NewTuple(3))
+
+	// resultA and resultB represent the *same* downstream fact reached via
+	// two distinct parent pairs (seqA+seqB1 and seqA+seqB2) — e.g. two
+	// different upstream derivations of a projected value that happen to
+	// coincide. What matters for this test is that they are Equals(), not
+	// that they came from the same Appending call.
+	resultA := NewSequence(NewTuple(1), NewTuple(2))
+	resultB := NewSequence(NewTuple(1), NewTuple(2))
+
+	if !node.addSequenceWithProvenance(resultA, provenance[int]{seqA, seqB1}) {
+		t.Fatal("expected the first justification to insert the result")
+	}
+	if node.addSequenceWithProvenance(resultB, provenance[int]{seqA, seqB2}) {
+		t.Fatal("expected a second justification of an already-present result not to report a fresh insert")
+	}
+	if len(node.sequences) != 1 {
+		t.Fatalf("want 1 sequence on file, got %d", len(node.sequences))
+	}
+
+	node.Retract(1, seqB1)
+	found := false
+	node.EnumSequences(func(s *Sequence[int]) {
+		if s.Equals(resultA) {
+			found = true
+		}
+	})
+	if !found {
+		t.Fatal("result was dropped even though a second justification still supports it")
+	}
+
+	node.Retract(1, seqB2)
+	node.EnumSequences(func(s *Sequence[int]) {
+		if s.Equals(resultA) {
+			t.Fatal("result should have been retracted once its last justification was gone")
+		}
+	})
+}
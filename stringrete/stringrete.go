@@ -0,0 +1,81 @@
+// Copyright 2019 Petr Homola. All rights reserved.
+// Use of this source code is governed by the AGPL v3.0
+// that can be found in the LICENSE file.
+
+// Package stringrete re-exports the rete package instantiated with
+// T = string, matching its pre-generics API so existing callers that work
+// with plain string components keep working unchanged.
+package stringrete
+
+import "github.com/phomola/rete"
+
+type (
+	Tuple        = rete.Tuple[string]
+	Sequence     = rete.Sequence[string]
+	Node         = rete.Node[string]
+	AlphaNode    = rete.AlphaNode[string]
+	BetaNode     = rete.BetaNode[string]
+	Network      = rete.Network[string]
+	Binding      = rete.Binding[string]
+	Matcher      = rete.Matcher[string]
+	Op           = rete.Op
+	OrderedIndex = rete.OrderedIndex[string, *Tuple]
+	SyncNetwork  = rete.SyncNetwork[string]
+)
+
+const (
+	Eq      = rete.Eq
+	Lt      = rete.Lt
+	Le      = rete.Le
+	Gt      = rete.Gt
+	Ge      = rete.Ge
+	Between = rete.Between
+)
+
+func NewTuple(comps ...string) *Tuple {
+	return rete.NewTuple(comps...)
+}
+
+func NewSequence(tuples ...*Tuple) *Sequence {
+	return rete.NewSequence(tuples...)
+}
+
+func NewAlphaNode(sig string) *AlphaNode {
+	return rete.NewAlphaNode[string](sig)
+}
+
+func NewBetaNode() *BetaNode {
+	return rete.NewBetaNode[string]()
+}
+
+func NewNetwork() *Network {
+	return rete.NewNetwork[string]()
+}
+
+func Exact(value string) Matcher {
+	return rete.Exact(value)
+}
+
+func Any() Matcher {
+	return rete.Any[string]()
+}
+
+func Regex(pattern string) Matcher {
+	return rete.Regex[string](pattern)
+}
+
+func Glob(pattern string) Matcher {
+	return rete.Glob[string](pattern)
+}
+
+func NewAlphaPattern(sig string, matchers ...Matcher) *AlphaNode {
+	return rete.NewAlphaPattern(sig, matchers...)
+}
+
+func NewOrderedIndex(less func(a, b string) bool) *OrderedIndex {
+	return rete.NewOrderedIndex[string, *Tuple](less)
+}
+
+func NewSyncNetwork(workers int) *SyncNetwork {
+	return rete.NewSyncNetwork[string](workers)
+}